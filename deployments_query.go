@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DeploymentFilter narrows a deployment listing. Zero-value fields are
+// not applied to the query. It mirrors the GraphQL DeploymentFilter input
+// so the GraphQL resolvers can pass it straight through.
+type DeploymentFilter struct {
+	Team        string
+	Service     string
+	Environment string
+	Status      string
+	From        *time.Time
+	To          *time.Time
+}
+
+// DeploymentConnection is a cursor-paginated page of deployments, mirroring
+// the GraphQL DeploymentConnection type.
+type DeploymentConnection struct {
+	Nodes       []Deployment
+	EndCursor   *string
+	HasNextPage bool
+}
+
+// deploymentColumns maps a deployment field name to its column, used by
+// ListDeployments to project only the columns a caller actually asked
+// for (e.g. the set of fields a GraphQL query selected).
+var deploymentColumns = map[string]string{
+	"ID":          "deployment_id",
+	"Team":        "team",
+	"Service":     "service",
+	"Timestamp":   "timestamp",
+	"Duration":    "duration_minutes",
+	"Status":      "status",
+	"Environment": "environment",
+	"CommitHash":  "commit_hash",
+}
+
+// ListDeployments returns a page of deployments matching filter,
+// selecting only the requested fields (the rest are left at their zero
+// value on the returned Deployment). deployment_id is always selected
+// since it is used as the pagination cursor. Results are ordered by
+// deployment_id for stable, cursor-based pagination.
+func (ms *MetricsService) ListDeployments(filter DeploymentFilter, fields []string, after string, first int) ([]Deployment, string, bool, error) {
+	if first <= 0 {
+		first = 20
+	}
+
+	selected := map[string]bool{"ID": true}
+	for _, f := range fields {
+		selected[f] = true
+	}
+
+	var columns []string
+	for _, name := range []string{"ID", "Team", "Service", "Timestamp", "Duration", "Status", "Environment", "CommitHash"} {
+		if selected[name] {
+			columns = append(columns, deploymentColumns[name])
+		}
+	}
+
+	var where []string
+	var args []interface{}
+	argN := 1
+
+	addFilter := func(column, value string) {
+		if value == "" {
+			return
+		}
+		where = append(where, fmt.Sprintf("%s = $%d", column, argN))
+		args = append(args, value)
+		argN++
+	}
+	addFilter("team", filter.Team)
+	addFilter("service", filter.Service)
+	addFilter("environment", filter.Environment)
+	addFilter("status", filter.Status)
+
+	if filter.From != nil {
+		where = append(where, fmt.Sprintf("timestamp >= $%d", argN))
+		args = append(args, *filter.From)
+		argN++
+	}
+	if filter.To != nil {
+		where = append(where, fmt.Sprintf("timestamp <= $%d", argN))
+		args = append(args, *filter.To)
+		argN++
+	}
+
+	if after != "" {
+		cursor, err := decodeDeploymentCursor(after)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("invalid cursor: %v", err)
+		}
+		where = append(where, fmt.Sprintf("deployment_id > $%d", argN))
+		args = append(args, cursor)
+		argN++
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM deployments", strings.Join(columns, ", "))
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY deployment_id LIMIT %d", first+1)
+
+	rows, err := ms.db.Query(query, args...)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var deployments []Deployment
+	for rows.Next() {
+		d, err := scanDeploymentRow(rows, columns)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("scan failed: %v", err)
+		}
+		deployments = append(deployments, d)
+	}
+
+	hasNextPage := len(deployments) > first
+	if hasNextPage {
+		deployments = deployments[:first]
+	}
+
+	var endCursor string
+	if len(deployments) > 0 {
+		endCursor = encodeDeploymentCursor(deployments[len(deployments)-1].ID)
+	}
+
+	return deployments, endCursor, hasNextPage, nil
+}
+
+// scanDeploymentRow scans a row whose columns match, in order, the
+// requested deployment fields.
+func scanDeploymentRow(rows interface{ Scan(dest ...interface{}) error }, columns []string) (Deployment, error) {
+	var d Deployment
+	dest := make([]interface{}, len(columns))
+	for i, col := range columns {
+		switch col {
+		case "deployment_id":
+			dest[i] = &d.ID
+		case "team":
+			dest[i] = &d.Team
+		case "service":
+			dest[i] = &d.Service
+		case "timestamp":
+			dest[i] = &d.Timestamp
+		case "duration_minutes":
+			dest[i] = &d.Duration
+		case "status":
+			dest[i] = &d.Status
+		case "environment":
+			dest[i] = &d.Environment
+		case "commit_hash":
+			dest[i] = &d.CommitHash
+		}
+	}
+	return d, rows.Scan(dest...)
+}
+
+func encodeDeploymentCursor(id string) string {
+	return base64.StdEncoding.EncodeToString([]byte(id))
+}
+
+func decodeDeploymentCursor(cursor string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// severityWeightCase weights an incident's contribution to MTTR by
+// severity, so a handful of critical incidents move the number more than
+// the same count of low-severity ones.
+const severityWeightCase = `CASE severity
+	WHEN 'critical' THEN 4
+	WHEN 'high' THEN 3
+	WHEN 'medium' THEN 2
+	WHEN 'low' THEN 1
+	ELSE 1
+END`
+
+// DORAMetrics holds the four DORA keys for a single team over a window.
+type DORAMetrics struct {
+	Team                string  `json:"team"`
+	DeploymentFrequency float64 `json:"deployments_per_day"`
+	LeadTimeForChanges  float64 `json:"lead_time_hours"`
+	ChangeFailureRate   float64 `json:"change_failure_rate_pct"`
+	MTTRHours           float64 `json:"mttr_hours"`
+}
+
+// GetDORAMetrics computes the four DORA keys for a team by joining
+// deployments with pull_requests (via commit_hash, for lead time) and with
+// incidents (same team and service, with the deployment falling inside the
+// incident's start/end window, for change failure rate).
+func (ms *MetricsService) GetDORAMetrics(team string, window time.Duration) (DORAMetrics, error) {
+	windowDays := int(window.Hours() / 24)
+	if windowDays <= 0 {
+		windowDays = 1
+	}
+
+	query := `
+       WITH windowed_deployments AS (
+           SELECT *
+           FROM deployments
+           WHERE team = $1
+           AND timestamp >= (CURRENT_TIMESTAMP - INTERVAL (` + fmt.Sprintf("%d", windowDays) + `) DAYS)
+       ),
+       lead_times AS (
+           SELECT
+               MEDIAN(EXTRACT(EPOCH FROM (pr.merged_at - pr.created_at)) / 3600.0) as lead_time_hours
+           FROM pull_requests pr
+           JOIN windowed_deployments d ON d.commit_hash = pr.commit_hash
+           WHERE pr.team = $1 AND pr.merged_at IS NOT NULL
+       ),
+       failed_deployments AS (
+           SELECT COUNT(DISTINCT d.deployment_id) as failed_count
+           FROM windowed_deployments d
+           JOIN incidents i ON i.team = d.team AND i.service = d.service
+           WHERE d.timestamp BETWEEN i.start_time AND i.end_time
+       ),
+       mttr AS (
+           SELECT
+               SUM(EXTRACT(EPOCH FROM (end_time - start_time)) / 3600.0 * ` + severityWeightCase + `)
+                   / SUM(` + severityWeightCase + `) as mttr_hours
+           FROM incidents
+           WHERE team = $1
+           AND start_time >= (CURRENT_TIMESTAMP - INTERVAL (` + fmt.Sprintf("%d", windowDays) + `) DAYS)
+       )
+       SELECT
+           ROUND((SELECT COUNT(*) FROM windowed_deployments) * 1.0 / ` + fmt.Sprintf("%d", windowDays) + `, 2) as deployment_frequency,
+           ROUND(COALESCE((SELECT lead_time_hours FROM lead_times), 0), 2) as lead_time_hours,
+           ROUND(COALESCE((SELECT failed_count FROM failed_deployments), 0) * 100.0 /
+               GREATEST((SELECT COUNT(*) FROM windowed_deployments), 1), 2) as change_failure_rate,
+           ROUND(COALESCE((SELECT mttr_hours FROM mttr), 0), 2) as mttr_hours`
+
+	var dm DORAMetrics
+	dm.Team = team
+
+	row := ms.db.QueryRow(query, team)
+	if err := row.Scan(&dm.DeploymentFrequency, &dm.LeadTimeForChanges, &dm.ChangeFailureRate, &dm.MTTRHours); err != nil {
+		return DORAMetrics{}, fmt.Errorf("query failed: %v", err)
+	}
+
+	return dm, nil
+}
+
+func (h *Handler) getDORAMetrics(c *gin.Context) {
+	team := c.Query("team")
+	if team == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "team query parameter is required"})
+		return
+	}
+
+	windowDays := c.DefaultQuery("window_days", "30")
+	days, err := parsePositiveInt(windowDays, 30)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid window_days"})
+		return
+	}
+
+	metrics, err := h.service.GetDORAMetrics(team, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
+
+func parsePositiveInt(s string, fallback int) (int, error) {
+	var n int
+	if _, err := fmt.Sscanf(s, "%d", &n); err != nil || n <= 0 {
+		return fallback, nil
+	}
+	return n, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	duckdb "github.com/marcboeker/go-duckdb"
+)
+
+// duckdbStore is the original Store implementation, unchanged in
+// behavior from before the Store interface existed.
+type duckdbStore struct {
+	db *sql.DB
+}
+
+func (s *duckdbStore) Migrate() error {
+	schemas := []string{
+		`CREATE TABLE IF NOT EXISTS deployments (
+			deployment_id VARCHAR PRIMARY KEY,
+			team VARCHAR NOT NULL,
+			service VARCHAR NOT NULL,
+			timestamp TIMESTAMP NOT NULL,
+			duration_minutes INTEGER NOT NULL,
+			status VARCHAR NOT NULL,
+			environment VARCHAR NOT NULL,
+			commit_hash VARCHAR NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS incidents (
+			incident_id VARCHAR PRIMARY KEY,
+			team VARCHAR NOT NULL,
+			service VARCHAR NOT NULL,
+			start_time TIMESTAMP NOT NULL,
+			end_time TIMESTAMP NOT NULL,
+			severity VARCHAR NOT NULL,
+			resolved_by VARCHAR,
+			root_cause VARCHAR
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS pull_requests (
+			pr_id VARCHAR PRIMARY KEY,
+			team VARCHAR NOT NULL,
+			author VARCHAR NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			merged_at TIMESTAMP,
+			lines_added INTEGER,
+			lines_deletes INTEGER,
+			review_time_hours FLOAT,
+			status VARCHAR NOT NULL,
+			commit_hash VARCHAR
+		)`,
+	}
+
+	for _, schema := range schemas {
+		if _, err := s.db.Exec(schema); err != nil {
+			return fmt.Errorf("Failed to create schema: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *duckdbStore) InsertDeployments(deployments []Deployment) error {
+	conn, err := s.db.Conn(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %v", err)
+	}
+	defer conn.Close()
+
+	var appender *duckdb.Appender
+	err = conn.Raw(func(driverConn interface{}) error {
+		a, err := duckdb.NewAppenderFromConn(driverConn.(driver.Conn), "", "deployments")
+		if err != nil {
+			return err
+		}
+		appender = a
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create appender: %v", err)
+	}
+	defer appender.Close()
+
+	for _, d := range deployments {
+		err := appender.AppendRow(
+			d.ID, d.Team, d.Service, d.Timestamp, int32(d.Duration), d.Status, d.Environment, d.CommitHash,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to append row: %v", err)
+		}
+	}
+
+	return appender.Flush()
+}
+
+func (s *duckdbStore) GetTeamMetrics() ([]TeamMetrics, error) {
+	query := `
+       SELECT
+           team,
+           COUNT(*) as total_deployments,
+           SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) as successful_deployments,
+           ROUND(100.0 * SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) / COUNT(*), 2) as success_rate,
+           ROUND(AVG(CASE WHEN status = 'success' THEN duration_minutes END), 2) as avg_duration,
+           ROUND(COUNT(*) * 1.0 / 7, 2) as deployments_per_day
+       FROM deployments
+       GROUP BY team
+       ORDER BY success_rate DESC`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var metrics []TeamMetrics
+	for rows.Next() {
+		var tm TeamMetrics
+		err := rows.Scan(
+			&tm.Team,
+			&tm.TotalDeployments,
+			&tm.SuccessfulDeployments,
+			&tm.SuccessRate,
+			&tm.AvgDurationMinutes,
+			&tm.DeploymentFrequency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		metrics = append(metrics, tm)
+	}
+
+	return metrics, nil
+}
+
+func (s *duckdbStore) GetDailyMetrics(team string, days int) ([]DailyMetrics, error) {
+	query := `
+       SELECT
+           DATE_TRUNC('day', timestamp) as date,
+           team,
+           COUNT(*) as deployments,
+           SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) as successful,
+           ROUND(AVG(duration_minutes), 2) as avg_duration
+       FROM deployments
+       WHERE ($1 IS NULL OR team = $1)
+       AND timestamp >= (CURRENT_DATE - INTERVAL '$2 days')
+       GROUP BY DATE_TRUNC('day', timestamp), team
+       ORDER BY date DESC, team`
+
+	var teamParam interface{} = nil
+	if team != "" {
+		teamParam = team
+	}
+
+	// Use string interpolation for the interval since DuckDB doesn't accept parameters there
+	finalQuery := strings.Replace(query, "$2", strconv.Itoa(days), 1)
+
+	rows, err := s.db.Query(finalQuery, teamParam)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var metrics []DailyMetrics
+	for rows.Next() {
+		var dm DailyMetrics
+		var dateTime time.Time
+
+		err := rows.Scan(&dateTime, &dm.Team, &dm.Deployments, &dm.Successful, &dm.AvgDuration)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+
+		dm.Date = dateTime.Format("2006-01-02")
+		metrics = append(metrics, dm)
+	}
+
+	return metrics, nil
+}
+
+func (s *duckdbStore) AggregateDaily() error {
+	query := `CREATE OR REPLACE TABLE daily_team_summary AS
+        SELECT
+            DATE_TRUNC('day', timestamp) as date,
+            team,
+            COUNT(*) as total_deployments,
+            COUNT(*) FILTER (WHERE status = 'success') as successful_deployments,
+            ROUND(AVG(duration_minutes), 2) as avg_duration_minutes
+        FROM deployments
+        GROUP BY DATE_TRUNC('day', timestamp), team`
+
+	if _, err := s.db.Exec(query); err != nil {
+		return fmt.Errorf("aggregation query failed: %v", err)
+	}
+
+	return nil
+}
+
+func (s *duckdbStore) AggregateRankings() error {
+	query := `CREATE OR REPLACE TABLE team_rankings AS
+        SELECT
+            team,
+            RANK() OVER (ORDER BY COUNT(*) FILTER (WHERE status = 'success') * 100.0 / COUNT(*) DESC) as success_rank,
+            RANK() OVER (ORDER BY COUNT(*) DESC) as velocity_rank
+        FROM deployments
+        GROUP BY team`
+
+	if _, err := s.db.Exec(query); err != nil {
+		return fmt.Errorf("aggregation query failed: %v", err)
+	}
+
+	return nil
+}
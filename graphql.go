@@ -0,0 +1,372 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// The GraphQL API is built directly with graphql-go rather than gqlgen:
+// gqlgen generates its executable schema from schema.graphqls via a
+// separate `go generate` step, and this repo doesn't currently run that
+// step as part of the build. Building the schema in code keeps `go build`
+// self-contained while exposing the same Deployment/TeamMetrics/
+// DailyMetrics/DORAMetrics query surface.
+
+var timeScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Time",
+	Serialize: func(value interface{}) interface{} {
+		if t, ok := value.(time.Time); ok {
+			return t.Format(time.RFC3339)
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return t
+			}
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if lit, ok := valueAST.(*ast.StringValue); ok {
+			if t, err := time.Parse(time.RFC3339, lit.Value); err == nil {
+				return t
+			}
+		}
+		return nil
+	},
+})
+
+func deploymentField(name string, fieldType graphql.Output, get func(Deployment) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Name: name,
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			d, ok := p.Source.(Deployment)
+			if !ok {
+				return nil, nil
+			}
+			return get(d), nil
+		},
+	}
+}
+
+var deploymentType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Deployment",
+	Fields: graphql.Fields{
+		"deploymentId":    deploymentField("deploymentId", graphql.String, func(d Deployment) interface{} { return d.ID }),
+		"team":            deploymentField("team", graphql.String, func(d Deployment) interface{} { return d.Team }),
+		"service":         deploymentField("service", graphql.String, func(d Deployment) interface{} { return d.Service }),
+		"timestamp":       deploymentField("timestamp", timeScalar, func(d Deployment) interface{} { return d.Timestamp }),
+		"durationMinutes": deploymentField("durationMinutes", graphql.Int, func(d Deployment) interface{} { return d.Duration }),
+		"status":          deploymentField("status", graphql.String, func(d Deployment) interface{} { return d.Status }),
+		"environment":     deploymentField("environment", graphql.String, func(d Deployment) interface{} { return d.Environment }),
+		"commitHash":      deploymentField("commitHash", graphql.String, func(d Deployment) interface{} { return d.CommitHash }),
+	},
+})
+
+var deploymentFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "DeploymentFilter",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"team":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"service":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"environment": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"status":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"from":        &graphql.InputObjectFieldConfig{Type: timeScalar},
+		"to":          &graphql.InputObjectFieldConfig{Type: timeScalar},
+	},
+})
+
+func connectionField(name string, fieldType graphql.Output, get func(*DeploymentConnection) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Name: name,
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			conn, ok := p.Source.(*DeploymentConnection)
+			if !ok {
+				return nil, nil
+			}
+			return get(conn), nil
+		},
+	}
+}
+
+var deploymentConnectionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "DeploymentConnection",
+	Fields: graphql.Fields{
+		"nodes":       connectionField("nodes", graphql.NewList(deploymentType), func(c *DeploymentConnection) interface{} { return c.Nodes }),
+		"endCursor":   connectionField("endCursor", graphql.String, func(c *DeploymentConnection) interface{} { return c.EndCursor }),
+		"hasNextPage": connectionField("hasNextPage", graphql.Boolean, func(c *DeploymentConnection) interface{} { return c.HasNextPage }),
+	},
+})
+
+func teamMetricsField(name string, fieldType graphql.Output, get func(TeamMetrics) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Name: name,
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			tm, ok := p.Source.(TeamMetrics)
+			if !ok {
+				return nil, nil
+			}
+			return get(tm), nil
+		},
+	}
+}
+
+var teamMetricsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TeamMetrics",
+	Fields: graphql.Fields{
+		"team":                  teamMetricsField("team", graphql.String, func(tm TeamMetrics) interface{} { return tm.Team }),
+		"totalDeployments":      teamMetricsField("totalDeployments", graphql.Int, func(tm TeamMetrics) interface{} { return tm.TotalDeployments }),
+		"successfulDeployments": teamMetricsField("successfulDeployments", graphql.Int, func(tm TeamMetrics) interface{} { return tm.SuccessfulDeployments }),
+		"successRatePct":        teamMetricsField("successRatePct", graphql.Float, func(tm TeamMetrics) interface{} { return tm.SuccessRate }),
+		"avgDurationMinutes":    teamMetricsField("avgDurationMinutes", graphql.Float, func(tm TeamMetrics) interface{} { return tm.AvgDurationMinutes }),
+		"deploymentsPerDay":     teamMetricsField("deploymentsPerDay", graphql.Float, func(tm TeamMetrics) interface{} { return tm.DeploymentFrequency }),
+	},
+})
+
+func dailyMetricsField(name string, fieldType graphql.Output, get func(DailyMetrics) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Name: name,
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			dm, ok := p.Source.(DailyMetrics)
+			if !ok {
+				return nil, nil
+			}
+			return get(dm), nil
+		},
+	}
+}
+
+var dailyMetricsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "DailyMetrics",
+	Fields: graphql.Fields{
+		"date":        dailyMetricsField("date", graphql.String, func(dm DailyMetrics) interface{} { return dm.Date }),
+		"team":        dailyMetricsField("team", graphql.String, func(dm DailyMetrics) interface{} { return dm.Team }),
+		"deployments": dailyMetricsField("deployments", graphql.Int, func(dm DailyMetrics) interface{} { return dm.Deployments }),
+		"successful":  dailyMetricsField("successful", graphql.Int, func(dm DailyMetrics) interface{} { return dm.Successful }),
+		"avgDuration": dailyMetricsField("avgDuration", graphql.Float, func(dm DailyMetrics) interface{} { return dm.AvgDuration }),
+	},
+})
+
+func doraMetricsField(name string, fieldType graphql.Output, get func(DORAMetrics) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Name: name,
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			dm, ok := p.Source.(DORAMetrics)
+			if !ok {
+				return nil, nil
+			}
+			return get(dm), nil
+		},
+	}
+}
+
+var doraMetricsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "DORAMetrics",
+	Fields: graphql.Fields{
+		"team":                 doraMetricsField("team", graphql.String, func(dm DORAMetrics) interface{} { return dm.Team }),
+		"deploymentsPerDay":    doraMetricsField("deploymentsPerDay", graphql.Float, func(dm DORAMetrics) interface{} { return dm.DeploymentFrequency }),
+		"leadTimeHours":        doraMetricsField("leadTimeHours", graphql.Float, func(dm DORAMetrics) interface{} { return dm.LeadTimeForChanges }),
+		"changeFailureRatePct": doraMetricsField("changeFailureRatePct", graphql.Float, func(dm DORAMetrics) interface{} { return dm.ChangeFailureRate }),
+		"mttrHours":            doraMetricsField("mttrHours", graphql.Float, func(dm DORAMetrics) interface{} { return dm.MTTRHours }),
+	},
+})
+
+// deploymentGoFieldNames maps a requested GraphQL field under
+// "deployments { nodes { ... } }" to the Deployment column ListDeployments
+// should select, so unrequested columns aren't fetched from DuckDB.
+var deploymentGoFieldNames = map[string]string{
+	"deploymentId":    "ID",
+	"team":            "Team",
+	"service":         "Service",
+	"timestamp":       "Timestamp",
+	"durationMinutes": "Duration",
+	"status":          "Status",
+	"environment":     "Environment",
+	"commitHash":      "CommitHash",
+}
+
+func requestedDeploymentFields(p graphql.ResolveParams) []string {
+	var fields []string
+	for _, fieldAST := range p.Info.FieldASTs {
+		if fieldAST.SelectionSet == nil {
+			continue
+		}
+		for _, sel := range fieldAST.SelectionSet.Selections {
+			nodesField, ok := sel.(*ast.Field)
+			if !ok || nodesField.Name == nil || nodesField.Name.Value != "nodes" || nodesField.SelectionSet == nil {
+				continue
+			}
+			for _, nodeSel := range nodesField.SelectionSet.Selections {
+				f, ok := nodeSel.(*ast.Field)
+				if !ok || f.Name == nil {
+					continue
+				}
+				if goName, ok := deploymentGoFieldNames[f.Name.Value]; ok {
+					fields = append(fields, goName)
+				}
+			}
+		}
+	}
+	return fields
+}
+
+func parseDeploymentFilter(args map[string]interface{}) DeploymentFilter {
+	var filter DeploymentFilter
+	raw, ok := args["filter"].(map[string]interface{})
+	if !ok {
+		return filter
+	}
+	if v, ok := raw["team"].(string); ok {
+		filter.Team = v
+	}
+	if v, ok := raw["service"].(string); ok {
+		filter.Service = v
+	}
+	if v, ok := raw["environment"].(string); ok {
+		filter.Environment = v
+	}
+	if v, ok := raw["status"].(string); ok {
+		filter.Status = v
+	}
+	if v, ok := raw["from"].(time.Time); ok {
+		filter.From = &v
+	}
+	if v, ok := raw["to"].(time.Time); ok {
+		filter.To = &v
+	}
+	return filter
+}
+
+func newGraphQLSchema(service *MetricsService) (graphql.Schema, error) {
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"deployments": &graphql.Field{
+				Type: deploymentConnectionType,
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: deploymentFilterInput},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					filter := parseDeploymentFilter(p.Args)
+					after, _ := p.Args["after"].(string)
+					first, _ := p.Args["first"].(int)
+
+					fields := requestedDeploymentFields(p)
+					deployments, endCursor, hasNextPage, err := service.ListDeployments(filter, fields, after, first)
+					if err != nil {
+						return nil, err
+					}
+
+					conn := &DeploymentConnection{Nodes: deployments, HasNextPage: hasNextPage}
+					if endCursor != "" {
+						conn.EndCursor = &endCursor
+					}
+					return conn, nil
+				},
+			},
+			"teamMetrics": &graphql.Field{
+				Type: graphql.NewList(teamMetricsType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return service.GetTeamMetrics()
+				},
+			},
+			"dailyMetrics": &graphql.Field{
+				Type: graphql.NewList(dailyMetricsType),
+				Args: graphql.FieldConfigArgument{
+					"team": &graphql.ArgumentConfig{Type: graphql.String},
+					"days": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 30},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					team, _ := p.Args["team"].(string)
+					days, _ := p.Args["days"].(int)
+					return service.GetDailyMetrics(team, days)
+				},
+			},
+			"doraMetrics": &graphql.Field{
+				Type: doraMetricsType,
+				Args: graphql.FieldConfigArgument{
+					"team":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"windowDays": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 30},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					team, _ := p.Args["team"].(string)
+					days, _ := p.Args["windowDays"].(int)
+					return service.GetDORAMetrics(team, time.Duration(days)*24*time.Hour)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func mountGraphQL(router *gin.Engine, service *MetricsService) error {
+	schema, err := newGraphQLSchema(service)
+	if err != nil {
+		return err
+	}
+
+	router.POST("/graphql", func(c *gin.Context) {
+		var req graphQLRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        c.Request.Context(),
+		})
+
+		c.JSON(http.StatusOK, result)
+	})
+
+	router.GET("/playground", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(graphiQLPage))
+	})
+
+	return nil
+}
+
+const graphiQLPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>GraphiQL</title>
+  <link href="https://unpkg.com/graphiql/graphiql.min.css" rel="stylesheet" />
+</head>
+<body style="margin:0;height:100vh">
+  <div id="graphiql" style="height:100vh"></div>
+  <script src="https://unpkg.com/react/umd/react.production.min.js"></script>
+  <script src="https://unpkg.com/react-dom/umd/react-dom.production.min.js"></script>
+  <script src="https://unpkg.com/graphiql/graphiql.min.js"></script>
+  <script>
+    ReactDOM.render(
+      React.createElement(GraphiQL, {
+        fetcher: GraphiQL.createFetcher({ url: '/graphql' }),
+      }),
+      document.getElementById('graphiql'),
+    );
+  </script>
+</body>
+</html>`
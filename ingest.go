@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/influxdata/line-protocol/v2/lineprotocol"
+	duckdb "github.com/marcboeker/go-duckdb"
+)
+
+// Point is a single parsed line-protocol measurement, ready to be routed to
+// a DuckDB table by measurement name.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Timestamp   time.Time
+}
+
+// writableMeasurements maps the line-protocol measurement name to the
+// DuckDB table it is inserted into. Anything not listed here is rejected.
+var writableMeasurements = map[string]string{
+	"deployments":   "deployments",
+	"incidents":     "incidents",
+	"pull_requests": "pull_requests",
+}
+
+func parseLineProtocol(body []byte) ([]Point, error) {
+	dec := lineprotocol.NewDecoderWithBytes(body)
+
+	var points []Point
+	for dec.Next() {
+		measurement, err := dec.Measurement()
+		if err != nil {
+			return nil, fmt.Errorf("invalid measurement: %v", err)
+		}
+
+		point := Point{
+			Measurement: string(measurement),
+			Tags:        make(map[string]string),
+			Fields:      make(map[string]interface{}),
+		}
+
+		for {
+			key, value, err := dec.NextTag()
+			if err != nil {
+				return nil, fmt.Errorf("invalid tag: %v", err)
+			}
+			if key == nil {
+				break
+			}
+			point.Tags[string(key)] = string(value)
+		}
+
+		for {
+			key, value, err := dec.NextField()
+			if err != nil {
+				return nil, fmt.Errorf("invalid field: %v", err)
+			}
+			if key == nil {
+				break
+			}
+			point.Fields[string(key)] = value.Interface()
+		}
+
+		ts, err := dec.Time(lineprotocol.Nanosecond, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp: %v", err)
+		}
+		point.Timestamp = ts
+
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// WriteBatch inserts a batch of already-parsed points for a single
+// measurement through a DuckDB appender, so a future gRPC or Kafka receiver
+// can reuse the same code path as the HTTP write endpoint.
+func (ms *MetricsService) WriteBatch(measurement string, points []Point) error {
+	table, ok := writableMeasurements[measurement]
+	if !ok {
+		return fmt.Errorf("unknown measurement: %s", measurement)
+	}
+
+	conn, err := ms.db.Conn(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %v", err)
+	}
+	defer conn.Close()
+
+	var appender *duckdb.Appender
+	err = conn.Raw(func(driverConn interface{}) error {
+		a, err := duckdb.NewAppenderFromConn(driverConn.(driver.Conn), "", table)
+		if err != nil {
+			return err
+		}
+		appender = a
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create appender for %s: %v", table, err)
+	}
+	defer appender.Close()
+
+	for _, p := range points {
+		row, err := rowForMeasurement(measurement, p)
+		if err != nil {
+			return err
+		}
+		if err := appender.AppendRow(row...); err != nil {
+			return fmt.Errorf("failed to append row: %v", err)
+		}
+	}
+
+	return appender.Flush()
+}
+
+// rowForMeasurement builds the column values for a point, in the column
+// order the corresponding table's schema declares.
+func rowForMeasurement(measurement string, p Point) ([]driver.Value, error) {
+	switch measurement {
+	case "deployments":
+		return []driver.Value{
+			stringField(p.Fields, "deployment_id"),
+			p.Tags["team"],
+			p.Tags["service"],
+			p.Timestamp,
+			intField(p.Fields, "duration_minutes"),
+			stringField(p.Fields, "status"),
+			p.Tags["environment"],
+			stringField(p.Fields, "commit_hash"),
+		}, nil
+	case "incidents":
+		return []driver.Value{
+			stringField(p.Fields, "incident_id"),
+			p.Tags["team"],
+			p.Tags["service"],
+			p.Timestamp,
+			endTimeField(p.Fields, p.Timestamp),
+			stringField(p.Fields, "severity"),
+			stringField(p.Fields, "resolved_by"),
+			stringField(p.Fields, "root_cause"),
+		}, nil
+	case "pull_requests":
+		return []driver.Value{
+			stringField(p.Fields, "pr_id"),
+			p.Tags["team"],
+			stringField(p.Fields, "author"),
+			p.Timestamp,
+			mergedAtField(p.Fields),
+			intField(p.Fields, "lines_added"),
+			intField(p.Fields, "lines_deletes"),
+			floatField(p.Fields, "review_time_hours"),
+			stringField(p.Fields, "status"),
+			stringField(p.Fields, "commit_hash"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown measurement: %s", measurement)
+	}
+}
+
+func stringField(fields map[string]interface{}, key string) string {
+	if v, ok := fields[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// intField returns an INTEGER-column value. DuckDB's INTEGER is 32-bit, so
+// this narrows rather than returning int64 like the line-protocol decoder does.
+func intField(fields map[string]interface{}, key string) int32 {
+	switch v := fields[key].(type) {
+	case int64:
+		return int32(v)
+	case float64:
+		return int32(v)
+	default:
+		return 0
+	}
+}
+
+func floatField(fields map[string]interface{}, key string) float64 {
+	switch v := fields[key].(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+func mergedAtField(fields map[string]interface{}) interface{} {
+	if v, ok := fields["merged_at"].(string); ok && v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	}
+	return nil
+}
+
+func endTimeField(fields map[string]interface{}, fallback time.Time) time.Time {
+	if v, ok := fields["end_time"].(string); ok && v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	}
+	return fallback
+}
+
+// writeLineProtocol handles POST /api/v1/write?bucket=... and ingests a
+// line-protocol body, batching inserts per measurement.
+func (h *Handler) writeLineProtocol(c *gin.Context) {
+	bucket := c.Query("bucket")
+	if bucket == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bucket query parameter is required"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to read body: %v", err)})
+		return
+	}
+
+	points, err := parseLineProtocol(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	byMeasurement := make(map[string][]Point)
+	for _, p := range points {
+		if _, ok := writableMeasurements[p.Measurement]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown measurement: %s", p.Measurement)})
+			return
+		}
+		byMeasurement[p.Measurement] = append(byMeasurement[p.Measurement], p)
+	}
+
+	for measurement, pts := range byMeasurement {
+		if err := h.service.WriteBatch(measurement, pts); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
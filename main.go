@@ -2,16 +2,16 @@ package main
 
 import (
 	"database/sql"
+	"encoding/csv"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-co-op/gocron"
-	_ "github.com/marcboeker/go-duckdb"
 )
 
 // Domain models
@@ -45,83 +45,45 @@ type DailyMetrics struct {
 
 // Services
 type MetricsService struct {
-	db *sql.DB
+	store Store
+	db    *sql.DB
 }
 
 func NewMetricsService() (*MetricsService, error) {
-	db, err := sql.Open("duckdb", "metrics.db")
+	store, db, err := newStore()
 	if err != nil {
-		return nil, fmt.Errorf("Failed to open DuckDB: %v", err)
+		return nil, err
 	}
 
-	service := &MetricsService{db: db}
+	service := &MetricsService{store: store, db: db}
 
-	if err := service.initializeSchema(); err != nil {
+	if err := service.store.Migrate(); err != nil {
 		return nil, fmt.Errorf("Failed to initialize schema: %v", err)
 	}
 
-	return service, nil
-}
-
-func (ms *MetricsService) initializeSchema() error {
-	schemas := []string{
-		`CREATE TABLE IF NOT EXISTS deployments (
-			deployment_id VARCHAR PRIMARY KEY,
-			team VARCHAR NOT NULL,
-			service VARCHAR NOT NULL,
-			timestamp TIMESTAMP NOT NULL,
-			duration_minutes INTEGER NOT NULL,
-			status VARCHAR NOT NULL,
-			environment VARCHAR NOT NULL,
-			commit_hash VARCHAR NOT NULL
-		)`,
-
-		`CREATE TABLE IF NOT EXISTS incidents (
-			incident_id VARCHAR PRIMARY KEY,
-			team VARCHAR NOT NULL,
-			service VARCHAR NOT NULL,
-			start_time TIMESTAMP NOT NULL,
-			end_time TIMESTAMP NOT NULL,
-			severity VARCHAR NOT NULL,
-			resolved_by VARCHAR,
-			root_cause VARCHAR
-		)`,
-
-		`CREATE TABLE IF NOT EXISTS pull_requests (
-			pr_id VARCHAR PRIMARY KEY,
-			team VARCHAR NOT NULL,
-			author VARCHAR NOT NULL,
-			created_at TIMESTAMP NOT NULL,
-			merged_at TIMESTAMP,
-			lines_added INTEGER,
-			lines_deletes INTEGER,
-			review_time_hours FLOAT,
-			status VARCHAR NOT NULL
-		)`,
+	if err := service.initializeStatusSchema(); err != nil {
+		return nil, fmt.Errorf("Failed to initialize status schema: %v", err)
 	}
 
-	for _, schema := range schemas {
-		if _, err := ms.db.Exec(schema); err != nil {
-			return fmt.Errorf("Failed to create schema: %v", err)
-		}
-	}
-
-	return nil
+	return service, nil
 }
 
+// LoadSampleData reseeds the deployments, incidents, and pull_requests
+// tables from the bundled example CSVs. Deployments are parsed here and
+// handed to the Store so both duckdbStore and pgStore can load them
+// through their own bulk-insert path; incidents and pull_requests still
+// load via DuckDB's read_csv_auto directly and are not yet part of the
+// Store abstraction.
 func (ms *MetricsService) LoadSampleData() error {
 	queries := []string{
 		"DELETE FROM deployments",
 		"DELETE FROM incidents",
 		"DELETE FROM pull_requests",
 
-		`INSERT INTO deployments
-		 SELECT * FROM read_csv_auto('example-data/deployments.csv', header=true)`,
-
 		`INSERT INTO incidents
 		 SELECT * FROM read_csv_auto('example-data/incidents.csv', header=true)`,
 
-		`INSERT INTO pull_requests
+		`INSERT INTO pull_requests (pr_id, team, author, created_at, merged_at, lines_added, lines_deletes, review_time_hours, status)
 		 SELECT * FROM read_csv_auto('example-data/pull_requests.csv', header=true)`,
 	}
 
@@ -132,122 +94,152 @@ func (ms *MetricsService) LoadSampleData() error {
 		}
 	}
 
-	log.Println("Sample data loaded successfully!")
-	return nil
-}
-
-// Business Logic
-func (ms *MetricsService) GetTeamMetrics() ([]TeamMetrics, error) {
-	query := `
-       SELECT 
-           team,
-           COUNT(*) as total_deployments,
-           SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) as successful_deployments,
-           ROUND(100.0 * SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) / COUNT(*), 2) as success_rate,
-           ROUND(AVG(CASE WHEN status = 'success' THEN duration_minutes END), 2) as avg_duration,
-           ROUND(COUNT(*) * 1.0 / 7, 2) as deployments_per_day
-       FROM deployments 
-       GROUP BY team
-       ORDER BY success_rate DESC`
-
-	rows, err := ms.db.Query(query)
+	deployments, err := readDeploymentsCSV("example-data/deployments.csv")
 	if err != nil {
-		return nil, fmt.Errorf("query failed: %v", err)
+		log.Printf("Warning: Failed to read deployments CSV: %v", err)
+		return err
+	}
+
+	if err := ms.store.InsertDeployments(deployments); err != nil {
+		log.Printf("Warning: Failed to insert deployments: %v", err)
+		return err
 	}
-	defer rows.Close()
-
-	var metrics []TeamMetrics
-	for rows.Next() {
-		var tm TeamMetrics
-		err := rows.Scan(
-			&tm.Team,
-			&tm.TotalDeployments,
-			&tm.SuccessfulDeployments,
-			&tm.SuccessRate,
-			&tm.AvgDurationMinutes,
-			&tm.DeploymentFrequency,
+
+	// The bundled pull_requests.csv has no commit_hash column, so
+	// lead-time-for-changes (which joins pull_requests to deployments on
+	// commit_hash) would have nothing to match against on this sample
+	// data. Backfill it from the nearest same-team deployment on or after
+	// the PR's merge, the same correlation a real CI/CD pipeline would
+	// use, so the DORA lead-time metric is exercisable out of the box.
+	// Production writes via WriteBatch already set commit_hash on both
+	// sides, so this backfill is sample-data-only.
+	if _, err := ms.db.Exec(`
+		UPDATE pull_requests
+		SET commit_hash = (
+			SELECT d.commit_hash
+			FROM deployments d
+			WHERE d.team = pull_requests.team
+			AND d.timestamp >= pull_requests.merged_at
+			ORDER BY d.timestamp ASC
+			LIMIT 1
 		)
-		if err != nil {
-			return nil, fmt.Errorf("scan failed: %v", err)
-		}
-		metrics = append(metrics, tm)
+		WHERE merged_at IS NOT NULL`); err != nil {
+		log.Printf("Warning: Failed to backfill pull_requests.commit_hash: %v", err)
+		return err
 	}
 
-	return metrics, nil
+	log.Println("Sample data loaded successfully!")
+	return nil
 }
 
-func (ms *MetricsService) GetDailyMetrics(team string, days int) ([]DailyMetrics, error) {
-	query := `
-       SELECT 
-           DATE_TRUNC('day', timestamp) as date,
-           team,
-           COUNT(*) as deployments,
-           SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) as successful,
-           ROUND(AVG(duration_minutes), 2) as avg_duration
-       FROM deployments 
-       WHERE ($1 IS NULL OR team = $1)
-       AND timestamp >= (CURRENT_DATE - INTERVAL '$2 days')
-       GROUP BY DATE_TRUNC('day', timestamp), team
-       ORDER BY date DESC, team`
-
-	var teamParam interface{} = nil
-	if team != "" {
-		teamParam = team
+// readDeploymentsCSV parses a deployments CSV with the same column order
+// as the deployments table (deployment_id, team, service, timestamp,
+// duration_minutes, status, environment, commit_hash).
+func readDeploymentsCSV(path string) ([]Deployment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
 	}
+	defer f.Close()
 
-	// Use string interpolation for the interval since DuckDB doesn't accept parameters there
-	finalQuery := strings.Replace(query, "$2", strconv.Itoa(days), 1)
-
-	rows, err := ms.db.Query(finalQuery, teamParam)
+	reader := csv.NewReader(f)
+	rows, err := reader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("query failed: %v", err)
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
 	}
-	defer rows.Close()
-
-	var metrics []DailyMetrics
-	for rows.Next() {
-		var dm DailyMetrics
-		var dateTime time.Time
 
-		err := rows.Scan(&dateTime, &dm.Team, &dm.Deployments, &dm.Successful, &dm.AvgDuration)
+	var deployments []Deployment
+	for _, row := range rows[1:] {
+		timestamp, err := time.Parse(time.RFC3339, row[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %v", row[3], err)
+		}
+		duration, err := strconv.Atoi(row[4])
 		if err != nil {
-			return nil, fmt.Errorf("scan failed: %v", err)
+			return nil, fmt.Errorf("invalid duration %q: %v", row[4], err)
 		}
 
-		dm.Date = dateTime.Format("2006-01-02")
-		metrics = append(metrics, dm)
+		deployments = append(deployments, Deployment{
+			ID:          row[0],
+			Team:        row[1],
+			Service:     row[2],
+			Timestamp:   timestamp,
+			Duration:    duration,
+			Status:      row[5],
+			Environment: row[6],
+			CommitHash:  row[7],
+		})
 	}
 
-	return metrics, nil
+	return deployments, nil
+}
+
+// Business Logic
+func (ms *MetricsService) GetTeamMetrics() ([]TeamMetrics, error) {
+	return ms.store.GetTeamMetrics()
+}
+
+func (ms *MetricsService) GetDailyMetrics(team string, days int) ([]DailyMetrics, error) {
+	return ms.store.GetDailyMetrics(team, days)
 }
 
 func (ms *MetricsService) aggregateMetrics() error {
 	log.Println("Running metrics aggregation...")
 
-	queries := []string{
-		`CREATE OR REPLACE TABLE daily_team_summary AS
-        SELECT
-            DATE_TRUNC('day', timestamp) as date,
-            team,
-            COUNT(*) as total_deployments,
-            COUNT(*) FILTER (WHERE status = 'success') as successful_deployments,
-            ROUND(AVG(duration_minutes), 2) as avg_duration_minutes
-        FROM deployments
-        GROUP BY DATE_TRUNC('day', timestamp), team`,
-
-		`CREATE OR REPLACE TABLE team_rankings AS
-        SELECT
-            team,
-            RANK() OVER (ORDER BY COUNT(*) FILTER (WHERE status = 'success') * 100.0 / COUNT(*) DESC) as success_rank,
-            RANK() OVER (ORDER BY COUNT(*) DESC) as velocity_rank
-        FROM deployments
-        GROUP BY team`,
+	if err := ms.store.AggregateDaily(); err != nil {
+		return err
 	}
 
-	for _, query := range queries {
-		if _, err := ms.db.Exec(query); err != nil {
-			return fmt.Errorf("aggregation query failed: %v", err)
-		}
+	if err := ms.store.AggregateRankings(); err != nil {
+		return err
+	}
+
+	// dora_team_summary stays DuckDB-specific SQL for now, alongside the
+	// other subsystems that reach through ms.db directly. Each metric is
+	// aggregated in its own CTE, same as GetDORAMetrics in dora.go, so the
+	// lead-time/MTTR medians aren't skewed by a fanned-out join.
+	query := `CREATE OR REPLACE TABLE dora_team_summary AS
+        WITH deployment_totals AS (
+            SELECT team, COUNT(*) as total_deployments
+            FROM deployments
+            GROUP BY team
+        ),
+        lead_times AS (
+            SELECT d.team, MEDIAN(EXTRACT(EPOCH FROM (pr.merged_at - pr.created_at)) / 3600.0) as lead_time_hours
+            FROM deployments d
+            JOIN pull_requests pr ON pr.commit_hash = d.commit_hash AND pr.team = d.team AND pr.merged_at IS NOT NULL
+            GROUP BY d.team
+        ),
+        failed_deployments AS (
+            SELECT d.team, COUNT(DISTINCT d.deployment_id) as failed_count
+            FROM deployments d
+            JOIN incidents i ON i.team = d.team AND i.service = d.service
+                AND d.timestamp BETWEEN i.start_time AND i.end_time
+            GROUP BY d.team
+        ),
+        mttr AS (
+            SELECT
+                team,
+                SUM(EXTRACT(EPOCH FROM (end_time - start_time)) / 3600.0 * ` + severityWeightCase + `) / SUM(` + severityWeightCase + `) as mttr_hours
+            FROM incidents
+            GROUP BY team
+        )
+        SELECT
+            dt.team,
+            ROUND(dt.total_deployments * 1.0 / 30, 2) as deployment_frequency,
+            ROUND(COALESCE(lt.lead_time_hours, 0), 2) as lead_time_hours,
+            ROUND(COALESCE(fd.failed_count, 0) * 100.0 / GREATEST(dt.total_deployments, 1), 2) as change_failure_rate,
+            ROUND(COALESCE(m.mttr_hours, 0), 2) as mttr_hours
+        FROM deployment_totals dt
+        LEFT JOIN lead_times lt ON lt.team = dt.team
+        LEFT JOIN failed_deployments fd ON fd.team = dt.team
+        LEFT JOIN mttr m ON m.team = dt.team`
+
+	if _, err := ms.db.Exec(query); err != nil {
+		return fmt.Errorf("aggregation query failed: %v", err)
 	}
 
 	log.Println("Metrics aggregation completed")
@@ -257,6 +249,7 @@ func (ms *MetricsService) aggregateMetrics() error {
 // HTTP Handlers
 type Handler struct {
 	service *MetricsService
+	prober  *Prober
 }
 
 func (h *Handler) getTeamMetrics(c *gin.Context) {
@@ -306,7 +299,12 @@ func main() {
 	})
 	scheduler.StartAsync()
 
-	handler := &Handler{service: service}
+	prober := NewProber(service)
+	if err := prober.Start(); err != nil {
+		log.Printf("Warning: Failed to start prober: %v", err)
+	}
+
+	handler := &Handler{service: service, prober: prober}
 	router := gin.Default()
 
 	api := router.Group("/api/v1")
@@ -315,12 +313,21 @@ func main() {
 	{
 		api.GET("/metrics/teams", handler.getTeamMetrics)
 		api.GET("/metrics/daily", handler.getDailyMetrics)
+		api.POST("/write", handler.writeLineProtocol)
+		api.GET("/metrics/dora", handler.getDORAMetrics)
+		api.GET("/status", handler.getStatus)
+		api.POST("/services", handler.registerServiceHandler)
+		api.DELETE("/services/:service_id", handler.unregisterServiceHandler)
 	}
 
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy", "database": "duckdb"})
 	})
 
+	if err := mountGraphQL(router, service); err != nil {
+		log.Fatal("Failed to build GraphQL schema:", err)
+	}
+
 	log.Println("Starting server on :8080")
 	if err := router.Run(":8080"); err != nil {
 		log.Fatal("Server failed to start:", err)
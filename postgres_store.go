@@ -0,0 +1,226 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	_ "github.com/lib/pq"
+)
+
+// pgStore is the Postgres-backed Store implementation. It translates the
+// DuckDB-specific SQL used by duckdbStore into standard Postgres:
+// FILTER (WHERE ...) becomes SUM(CASE WHEN ...), CREATE OR REPLACE TABLE
+// becomes DROP TABLE IF EXISTS followed by CREATE TABLE AS, and bulk CSV
+// loads go through COPY FROM STDIN instead of read_csv_auto.
+type pgStore struct {
+	db *sql.DB
+}
+
+func (s *pgStore) Migrate() error {
+	schemas := []string{
+		`CREATE TABLE IF NOT EXISTS deployments (
+			deployment_id VARCHAR PRIMARY KEY,
+			team VARCHAR NOT NULL,
+			service VARCHAR NOT NULL,
+			timestamp TIMESTAMP NOT NULL,
+			duration_minutes INTEGER NOT NULL,
+			status VARCHAR NOT NULL,
+			environment VARCHAR NOT NULL,
+			commit_hash VARCHAR NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS incidents (
+			incident_id VARCHAR PRIMARY KEY,
+			team VARCHAR NOT NULL,
+			service VARCHAR NOT NULL,
+			start_time TIMESTAMP NOT NULL,
+			end_time TIMESTAMP NOT NULL,
+			severity VARCHAR NOT NULL,
+			resolved_by VARCHAR,
+			root_cause VARCHAR
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS pull_requests (
+			pr_id VARCHAR PRIMARY KEY,
+			team VARCHAR NOT NULL,
+			author VARCHAR NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			merged_at TIMESTAMP,
+			lines_added INTEGER,
+			lines_deletes INTEGER,
+			review_time_hours FLOAT,
+			status VARCHAR NOT NULL,
+			commit_hash VARCHAR
+		)`,
+	}
+
+	for _, schema := range schemas {
+		if _, err := s.db.Exec(schema); err != nil {
+			return fmt.Errorf("failed to create schema: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *pgStore) InsertDeployments(deployments []Deployment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	stmt, err := tx.Prepare(`COPY deployments (deployment_id, team, service, timestamp, duration_minutes, status, environment, commit_hash)
+		FROM STDIN`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to prepare COPY: %v", err)
+	}
+
+	for _, d := range deployments {
+		if _, err := stmt.Exec(d.ID, d.Team, d.Service, d.Timestamp, d.Duration, d.Status, d.Environment, d.CommitHash); err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return fmt.Errorf("failed to copy row: %v", err)
+		}
+	}
+
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		tx.Rollback()
+		return fmt.Errorf("failed to flush COPY: %v", err)
+	}
+
+	if err := stmt.Close(); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to close COPY statement: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *pgStore) GetTeamMetrics() ([]TeamMetrics, error) {
+	query := `
+       SELECT
+           team,
+           COUNT(*) as total_deployments,
+           SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) as successful_deployments,
+           ROUND(100.0 * SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) / COUNT(*), 2) as success_rate,
+           ROUND(AVG(CASE WHEN status = 'success' THEN duration_minutes END), 2) as avg_duration,
+           ROUND(COUNT(*) * 1.0 / 7, 2) as deployments_per_day
+       FROM deployments
+       GROUP BY team
+       ORDER BY success_rate DESC`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var metrics []TeamMetrics
+	for rows.Next() {
+		var tm TeamMetrics
+		err := rows.Scan(
+			&tm.Team,
+			&tm.TotalDeployments,
+			&tm.SuccessfulDeployments,
+			&tm.SuccessRate,
+			&tm.AvgDurationMinutes,
+			&tm.DeploymentFrequency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		metrics = append(metrics, tm)
+	}
+
+	return metrics, nil
+}
+
+func (s *pgStore) GetDailyMetrics(team string, days int) ([]DailyMetrics, error) {
+	query := `
+       SELECT
+           DATE_TRUNC('day', timestamp) as date,
+           team,
+           COUNT(*) as deployments,
+           SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) as successful,
+           ROUND(AVG(duration_minutes)::numeric, 2) as avg_duration
+       FROM deployments
+       WHERE ($1::text IS NULL OR team = $1)
+       AND timestamp >= (CURRENT_DATE - ($2 || ' days')::interval)
+       GROUP BY DATE_TRUNC('day', timestamp), team
+       ORDER BY date DESC, team`
+
+	var teamParam interface{} = nil
+	if team != "" {
+		teamParam = team
+	}
+
+	rows, err := s.db.Query(query, teamParam, strconv.Itoa(days))
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var metrics []DailyMetrics
+	for rows.Next() {
+		var dm DailyMetrics
+		var dateTime sql.NullTime
+
+		err := rows.Scan(&dateTime, &dm.Team, &dm.Deployments, &dm.Successful, &dm.AvgDuration)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+
+		dm.Date = dateTime.Time.Format("2006-01-02")
+		metrics = append(metrics, dm)
+	}
+
+	return metrics, nil
+}
+
+func (s *pgStore) AggregateDaily() error {
+	queries := []string{
+		`DROP TABLE IF EXISTS daily_team_summary`,
+		`CREATE TABLE daily_team_summary AS
+        SELECT
+            DATE_TRUNC('day', timestamp) as date,
+            team,
+            COUNT(*) as total_deployments,
+            SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) as successful_deployments,
+            ROUND(AVG(duration_minutes)::numeric, 2) as avg_duration_minutes
+        FROM deployments
+        GROUP BY DATE_TRUNC('day', timestamp), team`,
+	}
+
+	for _, query := range queries {
+		if _, err := s.db.Exec(query); err != nil {
+			return fmt.Errorf("aggregation query failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *pgStore) AggregateRankings() error {
+	queries := []string{
+		`DROP TABLE IF EXISTS team_rankings`,
+		`CREATE TABLE team_rankings AS
+        SELECT
+            team,
+            RANK() OVER (ORDER BY SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) * 100.0 / COUNT(*) DESC) as success_rank,
+            RANK() OVER (ORDER BY COUNT(*) DESC) as velocity_rank
+        FROM deployments
+        GROUP BY team`,
+	}
+
+	for _, query := range queries {
+		if _, err := s.db.Exec(query); err != nil {
+			return fmt.Errorf("aggregation query failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-co-op/gocron"
+)
+
+// RegisteredService is a service the prober checks on a schedule.
+type RegisteredService struct {
+	ServiceID       string `json:"service_id" db:"service_id"`
+	Team            string `json:"team" db:"team"`
+	URL             string `json:"url" db:"url"`
+	CheckType       string `json:"check_type" db:"check_type"`
+	IntervalSeconds int    `json:"interval_seconds" db:"interval_seconds"`
+	ExpectedStatus  int    `json:"expected_status" db:"expected_status"`
+}
+
+// ServiceUptime is the current status plus rolling uptime windows for a
+// service, as returned by GET /api/v1/status.
+type ServiceUptime struct {
+	ServiceID string  `json:"service_id"`
+	Team      string  `json:"team"`
+	Up        bool    `json:"up"`
+	LatencyMs int     `json:"latency_ms"`
+	Uptime24h float64 `json:"uptime_24h_pct"`
+	Uptime7d  float64 `json:"uptime_7d_pct"`
+	Uptime30d float64 `json:"uptime_30d_pct"`
+}
+
+// consecutiveFailureThreshold is how many consecutive failed checks on a
+// service before the prober auto-opens an incident for it.
+const consecutiveFailureThreshold = 3
+
+// Prober schedules per-service health checks via gocron, writes each
+// result into DuckDB with its own INSERT as soon as the check completes
+// (results are not batched), and auto-opens incidents when a service fails
+// enough checks in a row.
+type Prober struct {
+	service   *MetricsService
+	scheduler *gocron.Scheduler
+	client    *http.Client
+
+	mu   sync.Mutex
+	jobs map[string]*gocron.Job
+}
+
+func NewProber(service *MetricsService) *Prober {
+	return &Prober{
+		service:   service,
+		scheduler: gocron.NewScheduler(time.UTC),
+		client:    &http.Client{Timeout: 10 * time.Second},
+		jobs:      make(map[string]*gocron.Job),
+	}
+}
+
+func (ms *MetricsService) initializeStatusSchema() error {
+	schemas := []string{
+		`CREATE TABLE IF NOT EXISTS services (
+			service_id VARCHAR PRIMARY KEY,
+			team VARCHAR NOT NULL,
+			url VARCHAR NOT NULL,
+			check_type VARCHAR NOT NULL,
+			interval_seconds INTEGER NOT NULL,
+			expected_status INTEGER NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS service_checks (
+			service_id VARCHAR NOT NULL,
+			checked_at TIMESTAMP NOT NULL,
+			latency_ms INTEGER NOT NULL,
+			up BOOLEAN NOT NULL,
+			status_code INTEGER
+		)`,
+	}
+
+	for _, schema := range schemas {
+		if _, err := ms.db.Exec(schema); err != nil {
+			return fmt.Errorf("failed to create status schema: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Start loads registered services and schedules a recurring check for
+// each one at its own interval.
+func (p *Prober) Start() error {
+	services, err := p.service.listServices()
+	if err != nil {
+		return fmt.Errorf("failed to load services: %v", err)
+	}
+
+	for _, svc := range services {
+		p.schedule(svc)
+	}
+
+	if _, err := p.scheduler.Every(1).Day().Do(func() {
+		if err := p.service.pruneOldChecks(90 * 24 * time.Hour); err != nil {
+			log.Printf("Failed to prune old service checks: %v", err)
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to schedule retention job: %v", err)
+	}
+
+	p.scheduler.StartAsync()
+	return nil
+}
+
+// pruneOldChecks deletes service_checks rows older than the retention
+// window, so the time-series table doesn't grow unbounded.
+func (ms *MetricsService) pruneOldChecks(retention time.Duration) error {
+	retentionDays := int(retention.Hours() / 24)
+	_, err := ms.db.Exec(
+		fmt.Sprintf(`DELETE FROM service_checks WHERE checked_at < CURRENT_TIMESTAMP - INTERVAL %d DAYS`, retentionDays),
+	)
+	return err
+}
+
+// schedule starts (or restarts) the recurring probe for svc. Re-registering
+// an already-scheduled service cancels its previous job first, so
+// re-registering never stacks duplicate timers.
+func (p *Prober) schedule(svc RegisteredService) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, ok := p.jobs[svc.ServiceID]; ok {
+		p.scheduler.RemoveByReference(existing)
+	}
+
+	job, err := p.scheduler.Every(svc.IntervalSeconds).Seconds().Do(func() {
+		p.check(svc)
+	})
+	if err != nil {
+		log.Printf("Failed to schedule probe for %s: %v", svc.ServiceID, err)
+		return
+	}
+
+	p.jobs[svc.ServiceID] = job
+}
+
+// unschedule cancels the recurring probe for serviceID, if one is running.
+func (p *Prober) unschedule(serviceID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	job, ok := p.jobs[serviceID]
+	if !ok {
+		return
+	}
+	p.scheduler.RemoveByReference(job)
+	delete(p.jobs, serviceID)
+}
+
+func (p *Prober) check(svc RegisteredService) {
+	var up bool
+	var statusCode int
+	var latency time.Duration
+
+	start := time.Now()
+	switch svc.CheckType {
+	case "http":
+		resp, err := p.client.Get(svc.URL)
+		latency = time.Since(start)
+		if err == nil {
+			statusCode = resp.StatusCode
+			up = statusCode == svc.ExpectedStatus
+			resp.Body.Close()
+		}
+	case "tcp":
+		conn, err := net.DialTimeout("tcp", svc.URL, p.client.Timeout)
+		latency = time.Since(start)
+		if err == nil {
+			up = true
+			conn.Close()
+		}
+	case "icmp":
+		conn, err := net.DialTimeout("ip4:icmp", svc.URL, p.client.Timeout)
+		latency = time.Since(start)
+		if err == nil {
+			up = true
+			conn.Close()
+		}
+	default:
+		log.Printf("Unknown check_type %q for service %s", svc.CheckType, svc.ServiceID)
+		return
+	}
+
+	if err := p.service.recordCheck(svc.ServiceID, latency, up, statusCode); err != nil {
+		log.Printf("Failed to record check for %s: %v", svc.ServiceID, err)
+		return
+	}
+
+	if !up {
+		p.maybeOpenIncident(svc)
+	}
+}
+
+func (ms *MetricsService) recordCheck(serviceID string, latency time.Duration, up bool, statusCode int) error {
+	_, err := ms.db.Exec(
+		`INSERT INTO service_checks (service_id, checked_at, latency_ms, up, status_code)
+		 VALUES ($1, CURRENT_TIMESTAMP, $2, $3, $4)`,
+		serviceID, latency.Milliseconds(), up, statusCode,
+	)
+	return err
+}
+
+// maybeOpenIncident auto-opens an incident for svc when the last
+// consecutiveFailureThreshold checks all failed and no incident opened by
+// the prober is still open for the service. Incidents opened by other
+// means are not checked.
+func (p *Prober) maybeOpenIncident(svc RegisteredService) {
+	failing, err := p.service.consecutiveFailures(svc.ServiceID, consecutiveFailureThreshold)
+	if err != nil {
+		log.Printf("Failed to check consecutive failures for %s: %v", svc.ServiceID, err)
+		return
+	}
+	if !failing {
+		return
+	}
+
+	if err := p.service.openProbeIncident(svc); err != nil {
+		log.Printf("Failed to auto-open incident for %s: %v", svc.ServiceID, err)
+	}
+}
+
+func (ms *MetricsService) consecutiveFailures(serviceID string, threshold int) (bool, error) {
+	row := ms.db.QueryRow(
+		`SELECT COUNT(*) FROM (
+			SELECT up FROM service_checks
+			WHERE service_id = $1
+			ORDER BY checked_at DESC
+			LIMIT $2
+		) recent WHERE up = false`,
+		serviceID, threshold,
+	)
+
+	var failedCount, recentCount int
+	if err := row.Scan(&failedCount); err != nil {
+		return false, err
+	}
+
+	row = ms.db.QueryRow(
+		`SELECT COUNT(*) FROM service_checks WHERE service_id = $1`, serviceID,
+	)
+	if err := row.Scan(&recentCount); err != nil {
+		return false, err
+	}
+
+	return failedCount >= threshold && recentCount >= threshold, nil
+}
+
+func (ms *MetricsService) openProbeIncident(svc RegisteredService) error {
+	var openIncidents int
+	row := ms.db.QueryRow(
+		`SELECT COUNT(*) FROM incidents
+		 WHERE service = $1 AND end_time >= CURRENT_TIMESTAMP AND root_cause = 'auto-opened by prober'`,
+		svc.ServiceID,
+	)
+	if err := row.Scan(&openIncidents); err != nil {
+		return err
+	}
+	if openIncidents > 0 {
+		return nil
+	}
+
+	_, err := ms.db.Exec(
+		`INSERT INTO incidents (incident_id, team, service, start_time, end_time, severity, resolved_by, root_cause)
+		 VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP + INTERVAL 1 HOUR, 'unknown', NULL, 'auto-opened by prober')`,
+		fmt.Sprintf("auto-%s-%d", svc.ServiceID, time.Now().UnixNano()), svc.Team, svc.ServiceID,
+	)
+	return err
+}
+
+func (ms *MetricsService) listServices() ([]RegisteredService, error) {
+	rows, err := ms.db.Query(`SELECT service_id, team, url, check_type, interval_seconds, expected_status FROM services`)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var services []RegisteredService
+	for rows.Next() {
+		var svc RegisteredService
+		if err := rows.Scan(&svc.ServiceID, &svc.Team, &svc.URL, &svc.CheckType, &svc.IntervalSeconds, &svc.ExpectedStatus); err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		services = append(services, svc)
+	}
+
+	return services, nil
+}
+
+func (ms *MetricsService) registerService(svc RegisteredService) error {
+	_, err := ms.db.Exec(
+		`INSERT INTO services (service_id, team, url, check_type, interval_seconds, expected_status)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (service_id) DO UPDATE SET team = $2, url = $3, check_type = $4, interval_seconds = $5, expected_status = $6`,
+		svc.ServiceID, svc.Team, svc.URL, svc.CheckType, svc.IntervalSeconds, svc.ExpectedStatus,
+	)
+	return err
+}
+
+func (ms *MetricsService) unregisterService(serviceID string) error {
+	_, err := ms.db.Exec(`DELETE FROM services WHERE service_id = $1`, serviceID)
+	return err
+}
+
+// GetUptime returns the current up/down state and rolling uptime
+// percentages for every registered service.
+func (ms *MetricsService) GetUptime() ([]ServiceUptime, error) {
+	query := `
+       WITH latest AS (
+           SELECT DISTINCT ON (service_id) service_id, up, latency_ms
+           FROM service_checks
+           ORDER BY service_id, checked_at DESC
+       )
+       SELECT
+           s.service_id,
+           s.team,
+           COALESCE(latest.up, false) as up,
+           COALESCE(latest.latency_ms, 0) as latency_ms,
+           ROUND(100.0 * AVG(CASE WHEN sc.checked_at >= CURRENT_TIMESTAMP - INTERVAL 1 DAY THEN CASE WHEN sc.up THEN 1 ELSE 0 END END), 2) as uptime_24h,
+           ROUND(100.0 * AVG(CASE WHEN sc.checked_at >= CURRENT_TIMESTAMP - INTERVAL 7 DAY THEN CASE WHEN sc.up THEN 1 ELSE 0 END END), 2) as uptime_7d,
+           ROUND(100.0 * AVG(CASE WHEN sc.checked_at >= CURRENT_TIMESTAMP - INTERVAL 30 DAY THEN CASE WHEN sc.up THEN 1 ELSE 0 END END), 2) as uptime_30d
+       FROM services s
+       LEFT JOIN latest ON latest.service_id = s.service_id
+       LEFT JOIN service_checks sc ON sc.service_id = s.service_id
+       GROUP BY s.service_id, s.team, latest.up, latest.latency_ms
+       ORDER BY s.service_id`
+
+	rows, err := ms.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	defer rows.Close()
+
+	var statuses []ServiceUptime
+	for rows.Next() {
+		var su ServiceUptime
+		var uptime24h, uptime7d, uptime30d sql.NullFloat64
+		if err := rows.Scan(&su.ServiceID, &su.Team, &su.Up, &su.LatencyMs, &uptime24h, &uptime7d, &uptime30d); err != nil {
+			return nil, fmt.Errorf("scan failed: %v", err)
+		}
+		su.Uptime24h = uptime24h.Float64
+		su.Uptime7d = uptime7d.Float64
+		su.Uptime30d = uptime30d.Float64
+		statuses = append(statuses, su)
+	}
+
+	return statuses, nil
+}
+
+func (h *Handler) getStatus(c *gin.Context) {
+	statuses, err := h.service.GetUptime()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
+
+func (h *Handler) registerServiceHandler(c *gin.Context) {
+	var svc RegisteredService
+	if err := c.ShouldBindJSON(&svc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.registerService(svc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.prober != nil {
+		h.prober.schedule(svc)
+	}
+
+	c.JSON(http.StatusCreated, svc)
+}
+
+func (h *Handler) unregisterServiceHandler(c *gin.Context) {
+	serviceID := c.Param("service_id")
+
+	if err := h.service.unregisterService(serviceID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.prober != nil {
+		h.prober.unschedule(serviceID)
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
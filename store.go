@@ -0,0 +1,56 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// Store is the persistence boundary for MetricsService. duckdbStore is the
+// original, DuckDB-backed implementation; pgStore lets deployments that
+// already run Postgres point the same service at it via STORAGE_DRIVER.
+//
+// Newer, DuckDB-specific subsystems (line-protocol ingestion, DORA
+// queries, the status-page prober) still reach through MetricsService.db
+// directly rather than through Store — they are not part of this
+// migration and remain DuckDB-only for now.
+type Store interface {
+	Migrate() error
+	InsertDeployments(deployments []Deployment) error
+	GetTeamMetrics() ([]TeamMetrics, error)
+	GetDailyMetrics(team string, days int) ([]DailyMetrics, error)
+	AggregateDaily() error
+	AggregateRankings() error
+}
+
+// newStore opens the backing database for the driver named by
+// STORAGE_DRIVER ("duckdb" by default) and returns both the Store and the
+// raw *sql.DB, since some handlers still query the DuckDB connection
+// directly.
+func newStore() (Store, *sql.DB, error) {
+	driver := os.Getenv("STORAGE_DRIVER")
+	if driver == "" {
+		driver = "duckdb"
+	}
+
+	switch driver {
+	case "duckdb":
+		db, err := sql.Open("duckdb", "metrics.db")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open DuckDB: %v", err)
+		}
+		return &duckdbStore{db: db}, db, nil
+	case "postgres":
+		dsn := os.Getenv("POSTGRES_DSN")
+		if dsn == "" {
+			dsn = "postgres://localhost:5432/metrics?sslmode=disable"
+		}
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open Postgres: %v", err)
+		}
+		return &pgStore{db: db}, db, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown STORAGE_DRIVER: %s", driver)
+	}
+}
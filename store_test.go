@@ -0,0 +1,90 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+)
+
+// storesUnderTest returns every Store implementation to run the shared
+// suite against. DuckDB runs in-memory and needs no setup; Postgres only
+// runs when POSTGRES_TEST_DSN points at a real instance (e.g. the service
+// container in CI), so the suite still runs locally without one.
+func storesUnderTest(t *testing.T) map[string]Store {
+	t.Helper()
+
+	stores := make(map[string]Store)
+
+	duckDB, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("failed to open in-memory DuckDB: %v", err)
+	}
+	t.Cleanup(func() { duckDB.Close() })
+	stores["duckdb"] = &duckdbStore{db: duckDB}
+
+	if dsn := os.Getenv("POSTGRES_TEST_DSN"); dsn != "" {
+		pgDB, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatalf("failed to open Postgres: %v", err)
+		}
+		t.Cleanup(func() { pgDB.Close() })
+		stores["postgres"] = &pgStore{db: pgDB}
+	} else {
+		t.Log("POSTGRES_TEST_DSN not set, skipping the postgres store in the shared suite")
+	}
+
+	return stores
+}
+
+func TestStoreDeploymentLifecycle(t *testing.T) {
+	for name, store := range storesUnderTest(t) {
+		name, store := name, store
+		t.Run(name, func(t *testing.T) {
+			if err := store.Migrate(); err != nil {
+				t.Fatalf("Migrate failed: %v", err)
+			}
+
+			now := time.Now().UTC().Truncate(time.Second)
+			deployments := []Deployment{
+				{ID: "d1", Team: "payments", Service: "api", Timestamp: now, Duration: 10, Status: "success", Environment: "prod", CommitHash: "abc"},
+				{ID: "d2", Team: "payments", Service: "api", Timestamp: now, Duration: 20, Status: "failed", Environment: "prod", CommitHash: "def"},
+			}
+			if err := store.InsertDeployments(deployments); err != nil {
+				t.Fatalf("InsertDeployments failed: %v", err)
+			}
+
+			metrics, err := store.GetTeamMetrics()
+			if err != nil {
+				t.Fatalf("GetTeamMetrics failed: %v", err)
+			}
+			if len(metrics) != 1 {
+				t.Fatalf("expected 1 team, got %d", len(metrics))
+			}
+			if metrics[0].TotalDeployments != 2 {
+				t.Errorf("expected 2 total deployments, got %d", metrics[0].TotalDeployments)
+			}
+			if metrics[0].SuccessfulDeployments != 1 {
+				t.Errorf("expected 1 successful deployment, got %d", metrics[0].SuccessfulDeployments)
+			}
+
+			daily, err := store.GetDailyMetrics("payments", 30)
+			if err != nil {
+				t.Fatalf("GetDailyMetrics failed: %v", err)
+			}
+			if len(daily) != 1 {
+				t.Fatalf("expected 1 daily row, got %d", len(daily))
+			}
+			if daily[0].Deployments != 2 {
+				t.Errorf("expected 2 deployments in daily metrics, got %d", daily[0].Deployments)
+			}
+
+			if err := store.AggregateDaily(); err != nil {
+				t.Fatalf("AggregateDaily failed: %v", err)
+			}
+			if err := store.AggregateRankings(); err != nil {
+				t.Fatalf("AggregateRankings failed: %v", err)
+			}
+		})
+	}
+}